@@ -0,0 +1,55 @@
+package backends
+
+import (
+	"slices"
+	"sync"
+)
+
+// PluginInfo describes a pluggable backend discovered by some backend implementation outside of
+// the normal Register call -- e.g. xla's Go-plugin (.so) discovery -- so that backend-agnostic
+// tooling (a "gomlx plugins" listing command, say) can enumerate them without importing that
+// backend's package directly.
+type PluginInfo struct {
+	// Name under which the plugin registered itself with Register.
+	Name string
+
+	// Version is an informational string reported by the plugin, if any.
+	Version string
+
+	// Priority as reported by the plugin; ListPlugins sorts higher-priority plugins first.
+	Priority int
+
+	// Source names the discovery mechanism that found the plugin, e.g. "xla-go-plugin".
+	Source string
+}
+
+var (
+	pluginListersMu sync.Mutex
+	pluginListers   []func() []PluginInfo
+)
+
+// RegisterPluginLister adds lister to the set ListPlugins consults.
+//
+// A backend implementation that discovers plugins outside of a normal Register call -- such as
+// xla's Go-plugin (.so) discovery -- calls this during init() so those plugins show up in the
+// combined, backend-agnostic listing.
+func RegisterPluginLister(lister func() []PluginInfo) {
+	pluginListersMu.Lock()
+	defer pluginListersMu.Unlock()
+	pluginListers = append(pluginListers, lister)
+}
+
+// ListPlugins reports every plugin discovered by a lister registered with RegisterPluginLister,
+// across every backend implementation that registered one.
+func ListPlugins() []PluginInfo {
+	pluginListersMu.Lock()
+	listers := slices.Clone(pluginListers)
+	pluginListersMu.Unlock()
+
+	var all []PluginInfo
+	for _, lister := range listers {
+		all = append(all, lister()...)
+	}
+	slices.SortStableFunc(all, func(a, b PluginInfo) int { return b.Priority - a.Priority })
+	return all
+}
@@ -0,0 +1,235 @@
+package xla
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gomlx/exceptions"
+	"github.com/gomlx/gopjrt/pjrt"
+	"github.com/pkg/errors"
+)
+
+// DistributedConfig configures multi-process, multi-device execution for the xla backend.
+//
+// It mirrors the handful of flags JAX's xla_bridge passes down to its distributed runtime
+// during initialization: where to find the coordinator service, which process this is, how
+// many processes are taking part, and which local devices this process should contribute.
+type DistributedConfig struct {
+	// CoordinatorAddress is the "host:port" of the coordinator service every process connects
+	// to during initialization. Only the process with ProcessID == 0 needs to host it.
+	CoordinatorAddress string
+
+	// ProcessID of this process, in [0, NumProcesses).
+	ProcessID int
+
+	// NumProcesses taking part in the distributed computation.
+	NumProcesses int
+
+	// VisibleDevices restricts which local devices this process contributes. If empty, all
+	// devices reported by the plugin for this process are used.
+	VisibleDevices []int
+}
+
+// distributedConfigs tracks the DistributedConfig a Backend was created with, so Devices()
+// can tell local devices from global ones without adding distributed-only fields to Backend.
+var (
+	distributedConfigsMu sync.Mutex
+	distributedConfigs   = map[*Backend]*DistributedConfig{}
+)
+
+// toNamedValues translates the config into the key/value pairs the PJRT client constructor
+// expects, following the same naming JAX uses for its distributed client options.
+func (d *DistributedConfig) toNamedValues() pjrt.NamedValuesMap {
+	if d == nil {
+		return nil
+	}
+	values := pjrt.NamedValuesMap{
+		"distributed_coordinator_address": d.CoordinatorAddress,
+		"distributed_process_id":          d.ProcessID,
+		"distributed_num_processes":       d.NumProcesses,
+	}
+	if len(d.VisibleDevices) > 0 {
+		values["distributed_visible_devices"] = d.VisibleDevices
+	}
+	return values
+}
+
+// mergeNamedValues returns a NamedValuesMap with the entries of override layered on top of
+// base, favoring override on key collisions. Either argument may be nil.
+func mergeNamedValues(base, override pjrt.NamedValuesMap) pjrt.NamedValuesMap {
+	if len(base) == 0 {
+		return override
+	}
+	if len(override) == 0 {
+		return base
+	}
+	merged := make(pjrt.NamedValuesMap, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// NewDistributed creates a Backend configured for multi-process, multi-host SPMD execution.
+//
+// dist is merged into the options passed to the underlying pjrt.Client, so the plugin can
+// join the coordinator and discover the devices owned by the other processes. Once created,
+// use Backend.Devices to enumerate the resulting DeviceMesh.
+func NewDistributed(pluginName string, options pjrt.NamedValuesMap, dist *DistributedConfig) *Backend {
+	merged := mergeNamedValues(options, dist.toNamedValues())
+	backend := NewWithOptions(pluginName, merged)
+	distributedConfigsMu.Lock()
+	distributedConfigs[backend] = dist
+	distributedConfigsMu.Unlock()
+	return backend
+}
+
+// DeviceMesh enumerates the devices a Backend can schedule SPMD programs onto: the ones
+// owned by this process (Local) and, for a distributed Backend, every device owned by every
+// process taking part in the same DistributedConfig (Global).
+type DeviceMesh struct {
+	// Local are the devices owned by this process.
+	Local []*pjrt.Device
+
+	// Global includes Local plus the devices reported by every other process taking part in
+	// the same DistributedConfig. For a single-process Backend, Global == Local.
+	Global []*pjrt.Device
+}
+
+// Devices returns the DeviceMesh backend can schedule SPMD programs onto.
+//
+// It panics if backend has already been Close'd: a closed backend holds no client to enumerate
+// devices from, and Close only returns once it knows no concurrent Devices/ExecuteSharded call
+// can still be touching the client (see clientMutex).
+func (backend *Backend) Devices() *DeviceMesh {
+	mu := clientMutex(backend)
+	mu.RLock()
+	defer mu.RUnlock()
+	return backend.devicesLocked()
+}
+
+// devicesLocked is the body of Devices, factored out so ExecuteSharded can reuse it while
+// already holding clientMutex(backend) for reading -- sync.RWMutex.RLock is not safely
+// re-entrant, so Devices must not call itself recursively through this path.
+func (backend *Backend) devicesLocked() *DeviceMesh {
+	if backend.client == nil {
+		exceptions.Panicf("xla: Devices called on closed backend %q", backend.pluginName)
+	}
+	local := backend.client.AddressableDevices()
+
+	distributedConfigsMu.Lock()
+	dist := distributedConfigs[backend]
+	distributedConfigsMu.Unlock()
+	if dist == nil || dist.NumProcesses <= 1 {
+		return &DeviceMesh{Local: local, Global: local}
+	}
+
+	// Global devices are reported by the plugin's client once it has joined the coordinator:
+	// client.Devices() includes devices owned by every process, while AddressableDevices()
+	// is restricted to this one.
+	return &DeviceMesh{Local: local, Global: backend.client.Devices()}
+}
+
+// ShardedBuffer wraps one pjrt.Buffer per device of a DeviceMesh, representing a single
+// logical value whose shards are spread across devices -- the result of compiling and running
+// an SPMD program.
+type ShardedBuffer struct {
+	Mesh   *DeviceMesh
+	Shards []*pjrt.Buffer
+}
+
+// ShardedExecutable wraps a pjrt.LoadedExecutable compiled for SPMD execution across a
+// DeviceMesh. It is distinct from the backend's regular Executable (returned by Backend.Compile
+// for ordinary, non-sharded programs) because it runs one program replica per device instead of
+// one program for the whole Backend.
+type ShardedExecutable struct {
+	backend *Backend
+	loaded  *pjrt.LoadedExecutable
+}
+
+// CompileSharded compiles programBytes -- a serialized XLA HLO module produced for SPMD
+// partitioning across backend's DeviceMesh -- into a ShardedExecutable.
+func (backend *Backend) CompileSharded(programBytes []byte, compileOptions pjrt.NamedValuesMap) (*ShardedExecutable, error) {
+	mu := clientMutex(backend)
+	mu.RLock()
+	defer mu.RUnlock()
+	if backend.client == nil {
+		return nil, errors.Errorf("CompileSharded: backend %q is closed", backend.pluginName)
+	}
+	loaded, err := backend.client.Compile(programBytes, compileOptions)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "backend %q: CompileSharded", backend.pluginName)
+	}
+	return &ShardedExecutable{backend: backend, loaded: loaded}, nil
+}
+
+// ExecuteSharded runs the executable with one shard per local device, dispatching all shards
+// in parallel and gathering their outputs into ShardedBuffer values.
+//
+// Each element of inputs must carry exactly one shard per local device of the executable's
+// DeviceMesh, in the same device order.
+func (e *ShardedExecutable) ExecuteSharded(inputs ...*ShardedBuffer) ([]*ShardedBuffer, error) {
+	mu := clientMutex(e.backend)
+	mu.RLock()
+	defer mu.RUnlock()
+	if e.backend.client == nil {
+		return nil, errors.Errorf("ExecuteSharded: backend %q is closed", e.backend.pluginName)
+	}
+	mesh := e.backend.devicesLocked()
+	numShards := len(mesh.Local)
+	for paramIdx, input := range inputs {
+		if len(input.Shards) != numShards {
+			return nil, errors.Errorf("ExecuteSharded: input #%d has %d shards, want %d (one per local device)",
+				paramIdx, len(input.Shards), numShards)
+		}
+	}
+
+	type shardResult struct {
+		outputs []*pjrt.Buffer
+		err     error
+	}
+	results := make([]shardResult, numShards)
+	var wg sync.WaitGroup
+	wg.Add(numShards)
+	for shardIdx := 0; shardIdx < numShards; shardIdx++ {
+		go func(shardIdx int) {
+			defer wg.Done()
+			shardInputs := make([]*pjrt.Buffer, len(inputs))
+			for paramIdx, input := range inputs {
+				shardInputs[paramIdx] = input.Shards[shardIdx]
+			}
+			outputs, err := e.loaded.Execute(shardInputs...).OnDevices(mesh.Local[shardIdx]).Done()
+			results[shardIdx] = shardResult{outputs: outputs, err: err}
+		}(shardIdx)
+	}
+	wg.Wait()
+
+	for shardIdx, result := range results {
+		if result.err != nil {
+			return nil, errors.WithMessagef(result.err, "ExecuteSharded: shard %d failed", shardIdx)
+		}
+	}
+	if numShards == 0 {
+		return nil, nil
+	}
+
+	numOutputs := len(results[0].outputs)
+	sharded := make([]*ShardedBuffer, numOutputs)
+	for outputIdx := 0; outputIdx < numOutputs; outputIdx++ {
+		shards := make([]*pjrt.Buffer, numShards)
+		for shardIdx, result := range results {
+			shards[shardIdx] = result.outputs[outputIdx]
+		}
+		sharded[outputIdx] = &ShardedBuffer{Mesh: mesh, Shards: shards}
+	}
+	return sharded, nil
+}
+
+// String implements fmt.Stringer, mostly for debugging/logging distributed runs.
+func (d *DistributedConfig) String() string {
+	return fmt.Sprintf("DistributedConfig(coordinator=%q, process=%d/%d, visible_devices=%v)",
+		d.CoordinatorAddress, d.ProcessID, d.NumProcesses, d.VisibleDevices)
+}
@@ -0,0 +1,143 @@
+package xla
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// cachedBackend tracks how many callers currently hold a reference to a Backend returned by
+// the New/getOrCreateBackend cache, so Close only tears down the underlying pjrt.Client once
+// the last one is done with it.
+type cachedBackend struct {
+	backend *Backend
+	count   int
+}
+
+var (
+	backendRegistryMu sync.Mutex
+	backendRegistry   = map[string]*cachedBackend{}
+
+	// backendConfigs maps a cached Backend back to its registry key, so Close doesn't need to
+	// scan backendRegistry to find the entry for a given *Backend.
+	backendConfigs = map[*Backend]string{}
+
+	// clientMutexes guards backend.client for every Backend (cached or not) against concurrent
+	// use and Close: readers (Devices, ExecuteSharded, CompileSharded, ...) take it for reading
+	// for as long as they touch the client, and closeClient takes it for writing before nilling
+	// and destroying the client. Because getOrCreateBackend can hand the same *Backend to many
+	// callers, this is the only thing standing between one caller's Close and another's
+	// concurrent use of a client that Close just destroyed. closeClient removes the entry for
+	// backend once its client is destroyed, so a closed Backend isn't rooted here forever.
+	clientMutexesMu sync.Mutex
+	clientMutexes   = map[*Backend]*sync.RWMutex{}
+)
+
+// clientMutex returns the sync.RWMutex guarding backend.client, creating one on first use.
+func clientMutex(backend *Backend) *sync.RWMutex {
+	clientMutexesMu.Lock()
+	defer clientMutexesMu.Unlock()
+	mu, ok := clientMutexes[backend]
+	if !ok {
+		mu = &sync.RWMutex{}
+		clientMutexes[backend] = mu
+	}
+	return mu
+}
+
+// getOrCreateBackend returns the live Backend for config, creating one with NewWithOptions if
+// none exists yet. Repeated calls with the same config string share the same Backend (and
+// underlying pjrt.Client) until every caller has called Close -- this is what lets long-running
+// services call New("cpu") freely without opening a new client, and device memory, every time.
+func getOrCreateBackend(config string) *Backend {
+	return getOrCreateBackendWith(config, func() *Backend { return NewWithOptions(config, nil) })
+}
+
+// getOrCreateBackendWith is getOrCreateBackend with the Backend construction step factored out,
+// so tests can exercise the reference-counting logic without a real PJRT plugin.
+func getOrCreateBackendWith(config string, construct func() *Backend) *Backend {
+	backendRegistryMu.Lock()
+	if cached, ok := backendRegistry[config]; ok {
+		cached.count++
+		backendRegistryMu.Unlock()
+		return cached.backend
+	}
+	backendRegistryMu.Unlock()
+
+	// Construct without holding backendRegistryMu: for a real Backend this blocks on
+	// plugin/device initialization, and we don't want that to stall unrelated New/Close calls.
+	backend := construct()
+
+	backendRegistryMu.Lock()
+	if cached, ok := backendRegistry[config]; ok {
+		// Another goroutine raced us and cached a Backend for config first: keep theirs and
+		// tear down the one we just built.
+		cached.count++
+		backendRegistryMu.Unlock()
+		backend.closeClient()
+		return cached.backend
+	}
+	backendRegistry[config] = &cachedBackend{backend: backend, count: 1}
+	backendConfigs[backend] = config
+	backendRegistryMu.Unlock()
+	return backend
+}
+
+// Close releases backend's reference to its underlying pjrt.Client and plugin.
+//
+// If backend was obtained through the New cache and other callers still hold it (e.g. another
+// call to New with the same config string), Close only decrements the reference count: the
+// client is destroyed once the last reference goes away. Backends created directly with
+// NewWithOptions are not cached and are destroyed immediately.
+//
+// Close synchronizes with Devices and ExecuteSharded (see clientMutex): it will block until any
+// concurrent call using backend's client finishes, and any such call started after Close
+// completes sees a closed backend instead of a destroyed client. Backend.SupressLogging is not
+// synchronized this way -- it mutates state shared by every holder of a cached Backend, so
+// callers that rely on the New cache and also call SupressLogging should coordinate among
+// themselves, the same way they would around any other shared resource.
+func (backend *Backend) Close() {
+	backendRegistryMu.Lock()
+	config, isCached := backendConfigs[backend]
+	if isCached {
+		cached := backendRegistry[config]
+		cached.count--
+		if cached.count > 0 {
+			backendRegistryMu.Unlock()
+			return
+		}
+		delete(backendRegistry, config)
+		delete(backendConfigs, backend)
+	}
+	backendRegistryMu.Unlock()
+
+	backend.closeClient()
+}
+
+// closeClient destroys backend's underlying pjrt.Client, bypassing the reference-counted cache.
+func (backend *Backend) closeClient() {
+	distributedConfigsMu.Lock()
+	delete(distributedConfigs, backend)
+	distributedConfigsMu.Unlock()
+
+	mu := clientMutex(backend)
+	mu.Lock()
+	alreadyClosed := backend.client == nil
+	var destroyErr error
+	if !alreadyClosed {
+		destroyErr = backend.client.Destroy()
+		backend.client = nil
+	}
+	mu.Unlock()
+
+	// backend.client is now nil for good (Backend has no re-open path), so clientMutex no longer
+	// needs to be kept around for it: drop the entry so backend can be garbage collected instead
+	// of being rooted forever by clientMutexes.
+	clientMutexesMu.Lock()
+	delete(clientMutexes, backend)
+	clientMutexesMu.Unlock()
+
+	if destroyErr != nil {
+		panic(errors.WithMessagef(destroyErr, "backend %q: Close", backend.pluginName))
+	}
+}
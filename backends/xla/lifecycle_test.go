@@ -0,0 +1,96 @@
+package xla
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestBackend returns a Backend with no real pjrt.Client -- enough to exercise the
+// reference-counting logic in lifecycle.go without a real PJRT plugin.
+func newTestBackend(pluginName string) *Backend {
+	return &Backend{pluginName: pluginName}
+}
+
+func TestGetOrCreateBackendWithSharesSingleInstance(t *testing.T) {
+	config := "test-shared-backend"
+	var constructions int64
+	construct := func() *Backend {
+		atomic.AddInt64(&constructions, 1)
+		return newTestBackend(config)
+	}
+
+	const numCallers = 20
+	backendsCh := make(chan *Backend, numCallers)
+	var wg sync.WaitGroup
+	wg.Add(numCallers)
+	for i := 0; i < numCallers; i++ {
+		go func() {
+			defer wg.Done()
+			backendsCh <- getOrCreateBackendWith(config, construct)
+		}()
+	}
+	wg.Wait()
+	close(backendsCh)
+
+	var first *Backend
+	for backend := range backendsCh {
+		if first == nil {
+			first = backend
+			continue
+		}
+		if backend != first {
+			t.Fatalf("expected every caller to get the same *Backend, got a different pointer")
+		}
+	}
+	if got := atomic.LoadInt64(&constructions); got != 1 {
+		t.Fatalf("expected exactly 1 construction for %d callers sharing config %q, got %d", numCallers, config, got)
+	}
+
+	// One Close per caller should bring the reference count back to zero and remove the entry.
+	for i := 0; i < numCallers; i++ {
+		first.Close()
+	}
+	if _, stillCached := lookupBackendRegistry(config); stillCached {
+		t.Fatalf("expected backend registry entry for %q to be removed after every caller closed", config)
+	}
+}
+
+func TestCloseDecrementsRefCountIndependently(t *testing.T) {
+	config := "test-refcount-backend"
+	backend := getOrCreateBackendWith(config, func() *Backend { return newTestBackend(config) })
+	second := getOrCreateBackendWith(config, func() *Backend {
+		t.Fatal("construct should not be called again for an already-cached config")
+		return nil
+	})
+	if second != backend {
+		t.Fatalf("expected the second caller to get the cached backend")
+	}
+
+	backend.Close()
+	if _, stillCached := lookupBackendRegistry(config); !stillCached {
+		t.Fatalf("expected backend to remain cached after only one of two references closed")
+	}
+
+	backend.Close()
+	if _, stillCached := lookupBackendRegistry(config); stillCached {
+		t.Fatalf("expected backend registry entry to be removed once both references closed")
+	}
+}
+
+func TestDifferentConfigsGetIndependentBackends(t *testing.T) {
+	a := getOrCreateBackendWith("config-a", func() *Backend { return newTestBackend("config-a") })
+	b := getOrCreateBackendWith("config-b", func() *Backend { return newTestBackend("config-b") })
+	if a == b {
+		t.Fatalf("expected different config strings to get different backends")
+	}
+	a.Close()
+	b.Close()
+}
+
+func lookupBackendRegistry(config string) (*cachedBackend, bool) {
+	backendRegistryMu.Lock()
+	defer backendRegistryMu.Unlock()
+	cached, ok := backendRegistry[config]
+	return cached, ok
+}
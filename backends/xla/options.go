@@ -0,0 +1,84 @@
+package xla
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gomlx/gopjrt/pjrt"
+	"github.com/pkg/errors"
+)
+
+// parseNamedValues parses the comma-separated "key=value" list found after the plugin name in
+// a config string -- e.g. "memory_fraction=0.8,preallocate=false,visible_devices=0,1" -- into a
+// pjrt.NamedValuesMap, typing each value as a bool, int, float64 or string.
+//
+// A bare token with no "=" is either a boolean flag (set to true, for backwards compatibility
+// with configs like "cuda,supress_logging") or, if it immediately follows a key whose value
+// parsed as an int, an extra element appended to that key's value, turning it into a []int --
+// e.g. "visible_devices=0,1,2" becomes NamedValuesMap{"visible_devices": []int{0, 1, 2}}.
+func parseNamedValues(parts []string) (pjrt.NamedValuesMap, error) {
+	values := make(pjrt.NamedValuesMap, len(parts))
+	var lastIntListKey string
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		if eqIdx := strings.IndexByte(part, '='); eqIdx >= 0 {
+			key, raw := part[:eqIdx], part[eqIdx+1:]
+			if key == "" {
+				return nil, errors.Errorf("xla: invalid plugin option %q: empty key", part)
+			}
+			value, isInt := parseScalarOption(raw)
+			values[key] = value
+			if isInt {
+				lastIntListKey = key
+			} else {
+				lastIntListKey = ""
+			}
+			continue
+		}
+
+		if lastIntListKey != "" {
+			if n, err := strconv.Atoi(part); err == nil {
+				switch existing := values[lastIntListKey].(type) {
+				case int:
+					values[lastIntListKey] = []int{existing, n}
+				case []int:
+					values[lastIntListKey] = append(existing, n)
+				}
+				continue
+			}
+			lastIntListKey = ""
+		}
+
+		// A bare token on its own is a boolean flag, e.g. "supress_logging".
+		values[part] = true
+	}
+	return values, nil
+}
+
+// parseScalarOption types a single "value" string as a bool, int, float64 or, failing those,
+// leaves it as a string. isInt reports whether it parsed as an int, which is the only type
+// parseNamedValues allows to grow into a list across subsequent bare tokens.
+func parseScalarOption(raw string) (value any, isInt bool) {
+	switch raw {
+	case "true":
+		return true, false
+	case "false":
+		return false, false
+	}
+	if n, err := strconv.Atoi(raw); err == nil {
+		return n, true
+	}
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		return f, false
+	}
+	return raw, false
+}
+
+// asBool returns the bool value, if any. It is used for options that started life as the old
+// bare-flag syntax (e.g. "supress_logging"), where a missing value means false.
+func asBool(value any) bool {
+	b, _ := value.(bool)
+	return b
+}
@@ -0,0 +1,90 @@
+package xla
+
+import "testing"
+
+func TestParseScalarOption(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    any
+		wantInt bool
+	}{
+		{raw: "true", want: true},
+		{raw: "false", want: false},
+		{raw: "42", want: 42, wantInt: true},
+		{raw: "-3", want: -3, wantInt: true},
+		{raw: "0.8", want: 0.8},
+		{raw: "cuda0", want: "cuda0"},
+	}
+	for _, test := range tests {
+		value, isInt := parseScalarOption(test.raw)
+		if value != test.want || isInt != test.wantInt {
+			t.Errorf("parseScalarOption(%q) = (%#v, %v), want (%#v, %v)", test.raw, value, isInt, test.want, test.wantInt)
+		}
+	}
+}
+
+func TestParseNamedValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []string
+		want  pjrtNamedValuesForTest
+	}{
+		{name: "empty", parts: nil, want: pjrtNamedValuesForTest{}},
+		{name: "ignores empty parts", parts: []string{"", "supress_logging", ""},
+			want: pjrtNamedValuesForTest{"supress_logging": true}},
+		{name: "bare flag", parts: []string{"supress_logging"},
+			want: pjrtNamedValuesForTest{"supress_logging": true}},
+		{name: "typed scalars", parts: []string{"memory_fraction=0.8", "preallocate=false", "name=cuda0"},
+			want: pjrtNamedValuesForTest{"memory_fraction": 0.8, "preallocate": false, "name": "cuda0"}},
+		{name: "int list continuation", parts: []string{"visible_devices=0", "1", "2"},
+			want: pjrtNamedValuesForTest{"visible_devices": []int{0, 1, 2}}},
+		{name: "bare flag after int list breaks continuation", parts: []string{"visible_devices=0", "1", "supress_logging"},
+			want: pjrtNamedValuesForTest{"visible_devices": []int{0, 1}, "supress_logging": true}},
+		{name: "non-numeric token after int key is its own flag", parts: []string{"retries=3", "cuda"},
+			want: pjrtNamedValuesForTest{"retries": 3, "cuda": true}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseNamedValues(test.parts)
+			if err != nil {
+				t.Fatalf("parseNamedValues(%v) returned error: %v", test.parts, err)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("parseNamedValues(%v) = %#v, want %#v", test.parts, got, test.want)
+			}
+			for key, wantValue := range test.want {
+				gotValue := got[key]
+				if !scalarOrIntSliceEqual(gotValue, wantValue) {
+					t.Errorf("parseNamedValues(%v)[%q] = %#v, want %#v", test.parts, key, gotValue, wantValue)
+				}
+			}
+		})
+	}
+}
+
+func TestParseNamedValuesRejectsEmptyKey(t *testing.T) {
+	if _, err := parseNamedValues([]string{"=0.8"}); err == nil {
+		t.Fatalf("parseNamedValues([\"=0.8\"]) should have returned an error for an empty key")
+	}
+}
+
+// pjrtNamedValuesForTest avoids importing gopjrt's types just for the test's expectation
+// literals: pjrt.NamedValuesMap is a map[string]any under the hood.
+type pjrtNamedValuesForTest = map[string]any
+
+func scalarOrIntSliceEqual(got, want any) bool {
+	gotInts, gotIsSlice := got.([]int)
+	wantInts, wantIsSlice := want.([]int)
+	if gotIsSlice || wantIsSlice {
+		if !gotIsSlice || !wantIsSlice || len(gotInts) != len(wantInts) {
+			return false
+		}
+		for i := range gotInts {
+			if gotInts[i] != wantInts[i] {
+				return false
+			}
+		}
+		return true
+	}
+	return got == want
+}
@@ -0,0 +1,204 @@
+package xla
+
+import (
+	"os"
+	"path/filepath"
+	"plugin"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gomlx/exceptions"
+	"github.com/gomlx/gomlx/backends"
+	"github.com/pkg/errors"
+)
+
+const (
+	// GoBackendPluginPathEnvVar names the environment variable holding a ":" separated list of
+	// directories to search for Go backend plugins (.so files), in addition to defaultGoBackendPluginDir.
+	GoBackendPluginPathEnvVar = "GOMLX_BACKEND_PLUGIN_PATH"
+
+	// RequiredGoBackendPluginsEnvVar names the environment variable holding a "," separated list of
+	// plugins that must load successfully -- discoverGoBackendPlugins panics if one fails. Entries
+	// may be either a full plugin path (matched exactly) or a bare file name such as "rocm.so"
+	// (matched against the basename of every discovered plugin, regardless of its directory).
+	RequiredGoBackendPluginsEnvVar = "GOMLX_REQUIRED_BACKEND_PLUGINS"
+
+	defaultGoBackendPluginDir = "/usr/local/lib/gomlx/backends"
+
+	// goBackendPluginSymbol is the name every Go backend plugin must export, of type *GoBackendPlugin.
+	goBackendPluginSymbol = "Backend"
+)
+
+// GoBackendPlugin is the symbol a Go plugin (.so) must export under the name "Backend" to be
+// picked up by discoverGoBackendPlugins. It pairs a backends.Backend constructor -- with the
+// same signature as New -- with the metadata used to register and report on it.
+//
+// This complements GetAvailablePlugins, which only discovers PJRT .so plugins: it lets third
+// parties ship a backend (e.g. an experimental IREE or ROCm backend) as a plain Go plugin,
+// without recompiling gomlx.
+type GoBackendPlugin struct {
+	// Name under which the plugin registers itself with backends.Register.
+	Name string
+
+	// Version is an informational string, reported by ListGoBackendPlugins.
+	Version string
+
+	// Priority orders the result of ListGoBackendPlugins, higher first. It has no effect on
+	// registration: backends.Register itself decides what happens on name collisions.
+	Priority int
+
+	// New constructs a Backend from a configuration string, exactly like New's signature.
+	New func(config string) backends.Backend
+}
+
+type discoveredGoBackendPlugin struct {
+	Path string
+	GoBackendPlugin
+}
+
+var (
+	goBackendPluginsMu sync.Mutex
+	goBackendPlugins   []discoveredGoBackendPlugin
+)
+
+func init() {
+	discoverGoBackendPlugins()
+	backends.RegisterPluginLister(listGoBackendPluginsAsPluginInfo)
+}
+
+// listGoBackendPluginsAsPluginInfo adapts ListGoBackendPlugins to the backends.PluginInfo shape
+// expected by backends.RegisterPluginLister / backends.ListPlugins, so tooling built against the
+// generic backends package can enumerate Go-plugin-discovered backends without importing xla.
+func listGoBackendPluginsAsPluginInfo() []backends.PluginInfo {
+	discovered := ListGoBackendPlugins()
+	infos := make([]backends.PluginInfo, len(discovered))
+	for i, desc := range discovered {
+		infos[i] = backends.PluginInfo{
+			Name:     desc.Name,
+			Version:  desc.Version,
+			Priority: desc.Priority,
+			Source:   "xla-go-plugin",
+		}
+	}
+	return infos
+}
+
+// discoverGoBackendPlugins walks the directories in GoBackendPluginPathEnvVar, plus
+// defaultGoBackendPluginDir (always searched, whether or not the env var is set), for compiled
+// Go plugins (.so files) and registers each one, under its reported Name, with backends.Register.
+//
+// Plugins are keyed by their full path, not their basename: the same path is never loaded
+// twice, but same-named files in different directories are each loaded and registered.
+// Paths or basenames listed in RequiredGoBackendPluginsEnvVar panic on failure instead of being
+// skipped.
+func discoverGoBackendPlugins() {
+	dirs := splitNonEmpty(os.Getenv(GoBackendPluginPathEnvVar), ":")
+	// defaultGoBackendPluginDir is always searched, on top of whatever GoBackendPluginPathEnvVar
+	// adds, so setting the env var augments rather than replaces it.
+	if !slices.Contains(dirs, defaultGoBackendPluginDir) {
+		dirs = append(dirs, defaultGoBackendPluginDir)
+	}
+	requiredPaths := make(map[string]bool)
+	requiredNames := make(map[string]bool)
+	for _, entry := range splitNonEmpty(os.Getenv(RequiredGoBackendPluginsEnvVar), ",") {
+		if strings.ContainsRune(entry, filepath.Separator) {
+			requiredPaths[filepath.Clean(entry)] = true
+		} else {
+			requiredNames[entry] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	seenNames := make(map[string]bool)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			// A missing plugin directory is the common case (no third-party plugins installed).
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".so" {
+				continue
+			}
+			path := filepath.Clean(filepath.Join(dir, entry.Name()))
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			seenNames[entry.Name()] = true
+			isRequired := requiredPaths[path] || requiredNames[entry.Name()]
+			loadGoBackendPlugin(path, isRequired)
+		}
+	}
+	for path := range requiredPaths {
+		if !seen[path] {
+			exceptions.Panicf("xla: required Go backend plugin %q failed to load: not found", path)
+		}
+	}
+	for name := range requiredNames {
+		if !seenNames[name] {
+			exceptions.Panicf("xla: required Go backend plugin %q failed to load: not found in %q", name, dirs)
+		}
+	}
+}
+
+func loadGoBackendPlugin(path string, isRequired bool) {
+	descriptor, err := readGoBackendPlugin(path)
+	if err != nil {
+		if isRequired {
+			exceptions.Panicf("xla: required Go backend plugin %q failed to load: %+v", path, err)
+		}
+		return
+	}
+
+	goBackendPluginsMu.Lock()
+	goBackendPlugins = append(goBackendPlugins, discoveredGoBackendPlugin{Path: path, GoBackendPlugin: descriptor})
+	goBackendPluginsMu.Unlock()
+	backends.Register(descriptor.Name, descriptor.New)
+}
+
+func readGoBackendPlugin(path string) (GoBackendPlugin, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return GoBackendPlugin{}, errors.WithMessagef(err, "opening plugin %q", path)
+	}
+	sym, err := p.Lookup(goBackendPluginSymbol)
+	if err != nil {
+		return GoBackendPlugin{}, errors.WithMessagef(err, "plugin %q does not export a %q symbol", path, goBackendPluginSymbol)
+	}
+	descriptor, ok := sym.(*GoBackendPlugin)
+	if !ok {
+		return GoBackendPlugin{}, errors.Errorf("plugin %q exports %q of type %T, want *xla.GoBackendPlugin", path, goBackendPluginSymbol, sym)
+	}
+	if descriptor.Name == "" {
+		return GoBackendPlugin{}, errors.Errorf("plugin %q: GoBackendPlugin.Name must not be empty", path)
+	}
+	return *descriptor, nil
+}
+
+// ListGoBackendPlugins reports the Go plugins discovered (and successfully registered) by
+// discoverGoBackendPlugins, sorted by descending Priority -- the Go-plugin equivalent of
+// GetAvailablePlugins' PJRT-plugin listing.
+//
+// It is also registered with backends.RegisterPluginLister (see listGoBackendPluginsAsPluginInfo),
+// so backend-agnostic tooling can get the same information through backends.ListPlugins without
+// importing this package.
+func ListGoBackendPlugins() []GoBackendPlugin {
+	goBackendPluginsMu.Lock()
+	defer goBackendPluginsMu.Unlock()
+	list := make([]GoBackendPlugin, len(goBackendPlugins))
+	for i, p := range goBackendPlugins {
+		list[i] = p.GoBackendPlugin
+	}
+	slices.SortFunc(list, func(a, b GoBackendPlugin) int { return b.Priority - a.Priority })
+	return list
+}
+
+func splitNonEmpty(s, sep string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, sep)
+	return slices.DeleteFunc(parts, func(s string) bool { return s == "" })
+}
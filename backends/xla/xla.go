@@ -19,14 +19,23 @@ import (
 
 const BackendName = "xla"
 
-// New returns a new Backend using the config as a configuration.
-// The config string should be the name of the PJRT plugin to use.
+// New returns a Backend for the given config, which should be the name of the PJRT plugin to
+// use (optionally followed by ",key=value,..." options, see NewWithOptions).
+//
+// Backends are reference-counted by their exact config string: calling New with the same config
+// more than once returns the same live Backend, and its underlying pjrt.Client is only destroyed
+// once every caller has called Backend.Close. Use NewWithOptions directly to always get a fresh,
+// uncached Backend.
 func New(pluginName string) backends.Backend {
-	return NewWithOptions(pluginName, nil)
+	return getOrCreateBackend(pluginName)
 }
 
 // NewWithOptions creates a XlaBackend with the given client options.
 // It allows more control, not available with the default New constructor.
+//
+// The config string after the plugin name is a comma-separated "key=value" list -- e.g.
+// "cuda,memory_fraction=0.8,preallocate=false,visible_devices=0,1" -- parsed by
+// parseNamedValues and merged into options (options takes precedence on key collisions).
 func NewWithOptions(pluginName string, options pjrt.NamedValuesMap) *Backend {
 	var pluginOptions []string
 	parts := strings.Split(pluginName, ",")
@@ -35,6 +44,11 @@ func NewWithOptions(pluginName string, options pjrt.NamedValuesMap) *Backend {
 		pluginOptions = slices.DeleteFunc(parts[1:], func(s string) bool { return s == "" })
 		pluginName = parts[0]
 	}
+	parsedOptions, err := parseNamedValues(pluginOptions)
+	if err != nil {
+		panic(errors.WithMessagef(err, "backend %q:", BackendName))
+	}
+	options = mergeNamedValues(parsedOptions, options)
 
 	plugins := GetAvailablePlugins()
 	if len(plugins) == 0 {
@@ -68,11 +82,15 @@ func NewWithOptions(pluginName string, options pjrt.NamedValuesMap) *Backend {
 		plugin:         plugin,
 		client:         client,
 		pluginName:     pluginName,
-		supressLogging: pluginName == "cuda" || slices.Index(pluginOptions, "supress_logging") != -1,
+		supressLogging: pluginName == "cuda" || asBool(parsedOptions["supress_logging"]),
 	}
 }
 
 // SupressLogging during compilation of a graph.
+//
+// backend may be shared with other callers if it came from the New cache (see getOrCreateBackend):
+// this setting is then shared too, so concurrent callers that both mutate it need to coordinate
+// among themselves, the same way they would around any other shared resource.
 func (backend *Backend) SupressLogging(supressLogging bool) *Backend {
 	backend.supressLogging = supressLogging
 	return backend